@@ -16,65 +16,52 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"encoding/binary"
-	"encoding/csv"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/spf13/cobra"
-	"io/ioutil"
 	"log"
-	"math"
-	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-)
 
-const (
-	completedList = "5e7d45a393cb705078c08e5b"
+	"github.com/decarboxy/mutual-aid-trello/export"
+	"github.com/decarboxy/mutual-aid-trello/schema"
+	"github.com/decarboxy/mutual-aid-trello/trelloclient"
+	"github.com/spf13/cobra"
 )
 
 var (
-	ApiKey     string
-	Token      string
-	OutputPath string
+	ApiKey      string
+	Token       string
+	OutputPath  string
+	BoardId     string
+	ListId      string
+	LabelFilter string
+	Concurrency int
+	Format      string
+	SchemaPath  string
 )
 
-type AmountPaidField struct {
-	Id            string            `json:"id"`
-	Value         map[string]string `json:"value"`
-	CustomfieldId string            `json:"idCustomField"`
-}
-
-func (f *AmountPaidField) GetAmountPaid() (amount int, err error) {
-	amount, err = strconv.Atoi(f.Value["number"])
-	return
-}
-
-type CardAction struct {
-	Id   string         `json:"id"`
-	Data CardActionData `json:"data"`
-	Date string         `json:"date"`
-	Type string         `json:"type"`
-}
-
-type CardActionData struct {
-	ListBefore CardActionItem `json:"listBefore"`
-	ListAfter  CardActionItem `json:"listAfter"`
-}
-
-type CardActionItem struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+// cardSchema is the single field list every exporter shares.
+var cardSchema = export.Schema{
+	"Name",
+	"Email",
+	"Institution",
+	"Location",
+	"Amount Paid",
+	"Reason",
+	"Fund Transfer Date",
+	"Request Date",
 }
 
 type TrelloCard struct {
-	Title            string `json:"name"`
-	Id               string `json:"id"`
-	Description      string `json:"desc"`
+	Title            string
+	Id               string
+	Description      string
 	Reason           string
 	Name             string
 	Email            string
@@ -85,20 +72,10 @@ type TrelloCard struct {
 	RequestDate      string
 }
 
-func (*TrelloCard) CsvHeader() []string {
-	return []string{
-		"Name",
-		"Email",
-		"Institution",
-		"Location",
-		"Amount Paid",
-		"Reason",
-		"Fund Transfer Date",
-		"Request Date"}
-}
-
-func (c *TrelloCard) CsvRow() []string {
-	return []string{
+// Record renders the card as a row matching cardSchema, so any
+// export.Exporter can consume it without knowing about TrelloCard.
+func (c *TrelloCard) Record() export.Record {
+	return export.Record{
 		c.Name,
 		c.Email,
 		c.Institution,
@@ -110,92 +87,46 @@ func (c *TrelloCard) CsvRow() []string {
 	}
 }
 
-func getAndBackoff(url string) (resp *http.Response, err error) {
-	retryLimit := 10
-	retryCount := 0
-	for true {
-		if retryCount >= retryLimit {
-			err = errors.New("retry limit exceeded")
-			return
-		}
-		resp, err = http.Get(url)
-		if err != nil {
-			return
-		}
-
-		if resp.StatusCode != 429 {
-			return
-		} else {
-			retryCount += 1
-			retryTime := int64(math.Pow(2, float64(retryCount)))
-			fmt.Printf("Being ratelimited, waiting %d and trying again\n", retryTime)
-			time.Sleep(time.Duration(retryTime) * time.Second)
-			continue
-		}
+// newTrelloCard translates a library card struct into our CSV row
+// model, leaving the fields that require extra API calls for Inflate.
+func newTrelloCard(card *trelloclient.Card) *TrelloCard {
+	return &TrelloCard{
+		Title:       card.Name,
+		Id:          card.ID,
+		Description: card.Desc,
 	}
-	return
 }
 
-func (c *TrelloCard) inflateAmountPaid(apiKey string, token string) (err error) {
-	getCustomFields := fmt.Sprintf("https://api.trello.com/1/cards/%s/customFieldItems?key=%s&token=%s", c.Id, apiKey, token)
-
-	resp, err := getAndBackoff(getCustomFields)
-	if err != nil {
-		return
-	}
-
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return
-	}
+// amountPaidFieldName is the Trello custom field holding the amount
+// sent on a completed request.
+const amountPaidFieldName = "Amount Paid"
 
-	var amountPaid []AmountPaidField
-	err = json.Unmarshal(body, &amountPaid)
-	if err != nil {
-		return
+func (c *TrelloCard) inflateAmountPaid(card *trelloclient.Card, customFields []*trelloclient.CustomField) (err error) {
+	raw, ok := card.CustomFields(customFields)[amountPaidFieldName]
+	if !ok {
+		return fmt.Errorf("%s is missing an amount paid value", c.Title)
 	}
 
-	if len(amountPaid) == 0 {
-		err = errors.New(fmt.Sprintf("%s is missing an amount paid value", c.Title))
-		return
+	switch v := raw.(type) {
+	case int:
+		c.AmountPaid = v
+	case float64:
+		c.AmountPaid = int(v)
+	default:
+		return fmt.Errorf("%s has an amount paid value of unexpected type %T", c.Title, raw)
 	}
-
-	//We only have 1 custom field
-	c.AmountPaid, err = amountPaid[0].GetAmountPaid()
-	return
+	return nil
 }
 
-func (c *TrelloCard) inflateCardHistory(apiKey string, token string) (err error) {
-	getActions := fmt.Sprintf("https://api.trello.com/1/cards/%s/actions?key=%s&token=%s&.filter=updateCard:idList", c.Id, apiKey, token)
-
-	resp, err := getAndBackoff(getActions)
+func (c *TrelloCard) inflateCardHistory(ctx context.Context, client *trelloclient.Client, card *trelloclient.Card, listId string) (err error) {
+	actions, err := client.CardActions(ctx, card)
 	if err != nil {
 		return
 	}
 
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return
-	}
-
-	var cardActions []CardAction
-	err = json.Unmarshal(body, &cardActions)
-	if err != nil {
-		return
-	}
-
-	for _, action := range cardActions {
-		if action.Type == "updateCard" && action.Data.ListAfter.Id == completedList {
-			var parsedTime time.Time
-			parsedTime, err = time.Parse(time.RFC3339, action.Date)
-			if err != nil {
-				return
-			}
-			c.FundTransferDate = parsedTime.Format("02 Jan 06 15:04")
+	for _, action := range actions {
+		if action.Type == "updateCard" && action.Data != nil && action.Data.ListAfter != nil && action.Data.ListAfter.ID == listId {
+			c.FundTransferDate = action.Date.Format("02 Jan 06 15:04")
 		}
 	}
 	return
@@ -216,30 +147,63 @@ func (c *TrelloCard) inflateRequestDate() (err error) {
 	return
 }
 
-func (c *TrelloCard) Inflate(apiKey string, token string) (err error) {
-	descriptionLines := strings.Split(c.Description, "\n")
-	for _, line := range descriptionLines {
-		fields := strings.Split(line, ":")
-		switch fields[0] {
-		case "Name":
-			c.Name = strings.TrimSpace(fields[1])
-		case "Email":
-			c.Email = strings.TrimSpace(fields[1])
-		case "Institution":
-			c.Institution = strings.TrimSpace(fields[1])
-		case "Location":
-			c.Location = strings.TrimSpace(fields[1])
-		case "Description":
-			c.Reason = strings.TrimSpace(fields[1])
+// destSetters maps a descSchema destination column to the TrelloCard
+// field it fills in.
+var destSetters = map[string]func(c *TrelloCard, value string){
+	"Name":        func(c *TrelloCard, value string) { c.Name = value },
+	"Email":       func(c *TrelloCard, value string) { c.Email = value },
+	"Institution": func(c *TrelloCard, value string) { c.Institution = value },
+	"Location":    func(c *TrelloCard, value string) { c.Location = value },
+	"Reason":      func(c *TrelloCard, value string) { c.Reason = value },
+}
+
+// validateSchemaColumns fails fast if a descSchema declares a Field
+// whose Column isn't one destSetters knows how to apply, instead of
+// letting Inflate silently skip it and export every card with that
+// column blank.
+func validateSchemaColumns(descSchema *schema.Schema) error {
+	var unknown []string
+	for _, field := range descSchema.Fields {
+		if _, ok := destSetters[field.Column]; !ok {
+			unknown = append(unknown, field.Column)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("schema declares unknown destination column(s): %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// customFieldValuesById returns a card's custom field values keyed by
+// field ID, for descSchema fields that match on a custom field instead
+// of the description text.
+func customFieldValuesById(card *trelloclient.Card) map[string]string {
+	values := make(map[string]string, len(card.CustomFieldItems))
+	for _, item := range card.CustomFieldItems {
+		if v := item.Value.Get(); v != nil {
+			values[item.IDCustomField] = fmt.Sprint(v)
+		}
+	}
+	return values
+}
+
+func (c *TrelloCard) Inflate(ctx context.Context, client *trelloclient.Client, card *trelloclient.Card, customFields []*trelloclient.CustomField, listId string, descSchema *schema.Schema) (err error) {
+	values, missing := descSchema.Extract(c.Description, customFieldValuesById(card))
+	if len(missing) > 0 {
+		return &schema.CardError{CardID: c.Id, CardTitle: c.Title, MissingFields: missing}
+	}
+	for column, value := range values {
+		if set, ok := destSetters[column]; ok {
+			set(c, value)
 		}
 	}
 
-	err = c.inflateAmountPaid(apiKey, token)
+	err = c.inflateAmountPaid(card, customFields)
 	if err != nil {
 		return
 	}
 
-	err = c.inflateCardHistory(apiKey, token)
+	err = c.inflateCardHistory(ctx, client, card, listId)
 	if err != nil {
 		return
 	}
@@ -248,56 +212,161 @@ func (c *TrelloCard) Inflate(apiKey string, token string) (err error) {
 	return
 }
 
+// inflateCardsConcurrently fans cards out across a bounded pool of
+// workers calling Inflate, and collects the results back in the same
+// order the cards were given in so the CSV output stays deterministic.
+// A card that fails schema validation is recorded in validationErrs
+// rather than aborting the run, so operators see every bad card in one
+// pass; any other error cancels ctx for the rest of the pool and is
+// returned once every worker has wound down.
+func inflateCardsConcurrently(ctx context.Context, client *trelloclient.Client, cards []*trelloclient.Card, customFields []*trelloclient.CustomField, listId string, concurrency int, descSchema *schema.Schema) (results []*TrelloCard, validationErrs []*schema.CardError, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results = make([]*TrelloCard, len(cards))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var once sync.Once
+	var firstErr error
+
+	fail := func(e error) {
+		once.Do(func() {
+			firstErr = e
+			cancel()
+		})
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				card := newTrelloCard(cards[idx])
+				inflateErr := card.Inflate(ctx, client, cards[idx], customFields, listId, descSchema)
+
+				var cardErr *schema.CardError
+				switch {
+				case inflateErr == nil:
+					results[idx] = card
+				case errors.As(inflateErr, &cardErr):
+					mu.Lock()
+					validationErrs = append(validationErrs, cardErr)
+					mu.Unlock()
+				default:
+					fail(inflateErr)
+					return
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range cards {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	return results, validationErrs, nil
+}
+
 // csvCmd represents the csv command
 var csvCmd = &cobra.Command{
 	Use:   "csv",
 	Short: "export requests in CSV form",
 	Run: func(cmd *cobra.Command, args []string) {
+		loadSavedCredentials()
+		if BoardId == "" || ListId == "" {
+			log.Fatal(errors.New("--board-id and --list-id are required"))
+		}
 
-		getCompletedCards := fmt.Sprintf("https://api.trello.com/1/lists/%s/cards?key=%s&token=%s", completedList, ApiKey, Token)
+		client := trelloclient.New(ApiKey, Token)
 
-		resp, err := getAndBackoff(getCompletedCards)
+		board, err := client.Board(BoardId)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		defer resp.Body.Close()
-
-		body, err := ioutil.ReadAll(resp.Body)
+		list, err := client.List(ListId)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		var cards []TrelloCard
-		err = json.Unmarshal(body, &cards)
+		customFields, err := client.CustomFields(board)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		outCsv, err := os.Create("output.csv")
+		cards, err := client.CardsInList(list)
 		if err != nil {
 			log.Fatal(err)
 		}
-		defer outCsv.Close()
 
-		csvWriter := csv.NewWriter(outCsv)
-		err = csvWriter.Write(cards[0].CsvHeader())
+		if LabelFilter != "" {
+			var filtered []*trelloclient.Card
+			for _, card := range cards {
+				if trelloclient.HasLabel(card, LabelFilter) {
+					filtered = append(filtered, card)
+				}
+			}
+			cards = filtered
+		}
+
+		if len(cards) == 0 {
+			log.Fatal(errors.New("no cards matched the given list/label"))
+		}
+
+		exporter, err := export.ForFormat(Format)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		for _, card := range cards {
-			err = card.Inflate(ApiKey, Token)
+		out := os.Stdout
+		if OutputPath != "-" {
+			out, err = os.Create(OutputPath)
 			if err != nil {
 				log.Fatal(err)
 			}
-			err = csvWriter.Write(card.CsvRow())
+			defer out.Close()
+		}
+
+		descSchema := schema.Default
+		if SchemaPath != "" {
+			descSchema, err = schema.Load(SchemaPath)
 			if err != nil {
 				log.Fatal(err)
 			}
+		}
+		if err := validateSchemaColumns(descSchema); err != nil {
+			log.Fatal(err)
+		}
 
+		inflated, validationErrs, err := inflateCardsConcurrently(context.Background(), client, cards, customFields, ListId, Concurrency, descSchema)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(validationErrs) > 0 {
+			log.Fatal(&schema.ValidationError{CardErrors: validationErrs})
 		}
 
+		records := make([]export.Record, len(inflated))
+		for i, card := range inflated {
+			records[i] = card.Record()
+		}
+
+		if err := exporter.Export(out, cardSchema, records); err != nil {
+			log.Fatal(err)
+		}
 	},
 }
 
@@ -306,14 +375,11 @@ func init() {
 
 	csvCmd.Flags().StringVar(&ApiKey, "api-key", "", "A trello API key")
 	csvCmd.Flags().StringVar(&Token, "token", "", "A trello Token")
-	csvCmd.Flags().StringVar(&OutputPath, "out", "recipients.csv", "the path to an output csv file")
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// csvCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// csvCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	csvCmd.Flags().StringVar(&OutputPath, "out", "recipients.csv", "the path to an output file, or - for stdout")
+	csvCmd.Flags().StringVar(&BoardId, "board-id", "", "the Trello board ID the completed list lives on")
+	csvCmd.Flags().StringVar(&ListId, "list-id", "", "the Trello list ID holding completed requests")
+	csvCmd.Flags().StringVar(&LabelFilter, "label", "", "only export cards carrying this label")
+	csvCmd.Flags().IntVar(&Concurrency, "concurrency", 8, "number of cards to inflate concurrently")
+	csvCmd.Flags().StringVar(&Format, "format", "csv", "output format: csv, json, or xlsx")
+	csvCmd.Flags().StringVar(&SchemaPath, "schema", "", "path to a YAML/JSON schema describing how to parse card descriptions")
 }
@@ -0,0 +1,153 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/decarboxy/mutual-aid-trello/schema"
+	"github.com/decarboxy/mutual-aid-trello/trelloclient"
+)
+
+const testAmountPaidFieldID = "field1"
+
+var testCustomFields = []*trelloclient.CustomField{
+	{ID: testAmountPaidFieldID, Name: amountPaidFieldName},
+}
+
+func TestValidateSchemaColumnsAcceptsKnownColumns(t *testing.T) {
+	if err := validateSchemaColumns(schema.Default); err != nil {
+		t.Fatalf("validateSchemaColumns(schema.Default) = %v, want nil", err)
+	}
+}
+
+func TestValidateSchemaColumnsRejectsUnknownColumn(t *testing.T) {
+	s := &schema.Schema{
+		Fields: []schema.Field{
+			{Column: "Name", Match: schema.KindPrefix, Pattern: "Name"},
+			{Column: "Intake Notes", Match: schema.KindPrefix, Pattern: "Notes"},
+		},
+	}
+
+	err := validateSchemaColumns(s)
+	if err == nil {
+		t.Fatal("expected an error for an unrecognized destination column, got nil")
+	}
+	if !strings.Contains(err.Error(), "Intake Notes") {
+		t.Errorf("error %q does not name the unknown column", err)
+	}
+}
+
+// testCardID produces a 24-character hex ID, matching the real format
+// Trello IDs take (inflateRequestDate decodes the first 8 characters as
+// a timestamp and would panic on anything shorter).
+func testCardID(i int) string {
+	return fmt.Sprintf("%08x%016x", i, i)
+}
+
+// cardsFixture renders n cards, each carrying an Amount Paid custom
+// field and a description Schema.Default can resolve, so the only
+// network call left in Inflate is CardActions.
+func cardsFixture(n int) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id": %q, "name": "Card %d", "desc": "Name: Card %d\nEmail: person%d@example.com", "customFieldItems": [{"idCustomField": %q, "value": {"number": "100"}}]}`, testCardID(i), i, i, i, testAmountPaidFieldID)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// newCardFetchingServer serves a fixed list of cards and routes every
+// card's actions request through actionsHandler, so tests can control
+// whether CardActions succeeds or fails per card.
+func newCardFetchingServer(t *testing.T, n int, actionsHandler http.HandlerFunc) *trelloclient.Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lists/list1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "list1"}`))
+	})
+	mux.HandleFunc("/lists/list1/cards", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cardsFixture(n)))
+	})
+	mux.HandleFunc("/cards/", actionsHandler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return trelloclient.New("key", "token").WithBaseURL(server.URL)
+}
+
+func TestInflateCardsConcurrentlyPreservesOrder(t *testing.T) {
+	client := newCardFetchingServer(t, 20, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+
+	list, err := client.List("list1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	cards, err := client.CardsInList(list)
+	if err != nil {
+		t.Fatalf("CardsInList: %v", err)
+	}
+
+	results, validationErrs, err := inflateCardsConcurrently(context.Background(), client, cards, testCustomFields, "list1", 4, schema.Default)
+	if err != nil {
+		t.Fatalf("inflateCardsConcurrently: %v", err)
+	}
+	if len(validationErrs) != 0 {
+		t.Fatalf("unexpected validation errors: %v", validationErrs)
+	}
+	if len(results) != len(cards) {
+		t.Fatalf("got %d results, want %d", len(results), len(cards))
+	}
+	for i, result := range results {
+		want := fmt.Sprintf("Card %d", i)
+		if result.Name != want {
+			t.Errorf("result[%d].Name = %q, want %q (ordering not preserved)", i, result.Name, want)
+		}
+	}
+}
+
+func TestInflateCardsConcurrentlyCancelsOnError(t *testing.T) {
+	client := newCardFetchingServer(t, 10, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	list, err := client.List("list1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	cards, err := client.CardsInList(list)
+	if err != nil {
+		t.Fatalf("CardsInList: %v", err)
+	}
+
+	_, _, err = inflateCardsConcurrently(context.Background(), client, cards, testCustomFields, "list1", 3, schema.Default)
+	if err == nil {
+		t.Fatal("expected an error once every card's CardActions call fails, got nil")
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/decarboxy/mutual-aid-trello/schema"
+	"github.com/decarboxy/mutual-aid-trello/trelloclient"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	SmtpHostname   string
+	SmtpPort       int
+	SmtpUsername   string
+	SmtpPassword   string
+	EmailFrom      string
+	EmailSubject   string
+	EmailDryRun    bool
+	EmailDryRunDir string
+)
+
+const defaultEmailBody = `Hi {{.Name}},
+
+Thanks for reaching out to us. We've sent {{.AmountPaid}} to {{.Institution}} on your behalf on {{.FundTransferDate}}.
+
+Take care,
+The mutual aid team
+`
+
+// renderEmail fills the message template with a single card's details.
+func renderEmail(subjectTmpl, bodyTmpl *template.Template, card *TrelloCard) (subject string, body string, err error) {
+	var subjectBuf, bodyBuf strings.Builder
+
+	if err = subjectTmpl.Execute(&subjectBuf, card); err != nil {
+		return
+	}
+	if err = bodyTmpl.Execute(&bodyBuf, card); err != nil {
+		return
+	}
+
+	return subjectBuf.String(), bodyBuf.String(), nil
+}
+
+func sendOrWriteEmail(card *TrelloCard, subject string, body string) error {
+	if EmailDryRun {
+		outPath := filepath.Join(EmailDryRunDir, fmt.Sprintf("%s.txt", card.Id))
+		contents := fmt.Sprintf("To: %s\nSubject: %s\n\n%s", card.Email, subject, body)
+		return os.WriteFile(outPath, []byte(contents), 0644)
+	}
+
+	addr := fmt.Sprintf("%s:%d", SmtpHostname, SmtpPort)
+	auth := smtp.PlainAuth("", SmtpUsername, SmtpPassword, SmtpHostname)
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", EmailFrom, card.Email, subject, body)
+
+	return smtp.SendMail(addr, auth, EmailFrom, []string{card.Email}, []byte(msg))
+}
+
+// emailCmd represents the email command
+var emailCmd = &cobra.Command{
+	Use:   "email",
+	Short: "send per-recipient confirmation emails for completed requests",
+	Run: func(cmd *cobra.Command, args []string) {
+		loadSavedCredentials()
+		if BoardId == "" || ListId == "" {
+			log.Fatal(errors.New("--board-id and --list-id are required"))
+		}
+
+		// Pick up the same options from MUTUAL_AID_TRELLO_* environment
+		// variables (e.g. MUTUAL_AID_TRELLO_SMTP_HOSTNAME) if they weren't
+		// passed as flags. No config file is read.
+		SmtpHostname = viper.GetString("smtp-hostname")
+		SmtpPort = viper.GetInt("smtp-port")
+		SmtpUsername = viper.GetString("smtp-username")
+		SmtpPassword = viper.GetString("smtp-password")
+		EmailFrom = viper.GetString("email-from")
+		EmailSubject = viper.GetString("email-subject")
+
+		if EmailDryRun {
+			if err := os.MkdirAll(EmailDryRunDir, 0755); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		subjectTmpl, err := template.New("subject").Parse(EmailSubject)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		bodyTmpl, err := template.New("body").Parse(defaultEmailBody)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		client := trelloclient.New(ApiKey, Token)
+
+		board, err := client.Board(BoardId)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		list, err := client.List(ListId)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		customFields, err := client.CustomFields(board)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		cards, err := client.CardsInList(list)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		descSchema := schema.Default
+		if SchemaPath != "" {
+			descSchema, err = schema.Load(SchemaPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if err := validateSchemaColumns(descSchema); err != nil {
+			log.Fatal(err)
+		}
+
+		var validationErrs []*schema.CardError
+		for _, raw := range cards {
+			card := newTrelloCard(raw)
+			if err := card.Inflate(context.Background(), client, raw, customFields, ListId, descSchema); err != nil {
+				var cardErr *schema.CardError
+				if errors.As(err, &cardErr) {
+					validationErrs = append(validationErrs, cardErr)
+					continue
+				}
+				log.Fatal(err)
+			}
+
+			if card.Email == "" {
+				log.Printf("skipping %s: no email address on file", card.Title)
+				continue
+			}
+
+			subject, body, err := renderEmail(subjectTmpl, bodyTmpl, card)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if err := sendOrWriteEmail(card, subject, body); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if len(validationErrs) > 0 {
+			log.Fatal(&schema.ValidationError{CardErrors: validationErrs})
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(emailCmd)
+
+	emailCmd.Flags().StringVar(&ApiKey, "api-key", "", "A trello API key")
+	emailCmd.Flags().StringVar(&Token, "token", "", "A trello Token")
+	emailCmd.Flags().StringVar(&BoardId, "board-id", "", "the Trello board ID the completed list lives on")
+	emailCmd.Flags().StringVar(&ListId, "list-id", "", "the Trello list ID holding completed requests")
+	emailCmd.Flags().StringVar(&SchemaPath, "schema", "", "path to a YAML/JSON schema describing how to parse card descriptions")
+
+	emailCmd.Flags().StringVar(&SmtpHostname, "smtp-hostname", "", "SMTP server hostname")
+	emailCmd.Flags().IntVar(&SmtpPort, "smtp-port", 587, "SMTP server port")
+	emailCmd.Flags().StringVar(&SmtpUsername, "smtp-username", "", "SMTP auth username")
+	emailCmd.Flags().StringVar(&SmtpPassword, "smtp-password", "", "SMTP auth password")
+	emailCmd.Flags().StringVar(&EmailFrom, "email-from", "", "the From address on outgoing emails")
+	emailCmd.Flags().StringVar(&EmailSubject, "email-subject", "Your mutual aid request has been completed", "the email subject template")
+	emailCmd.Flags().BoolVar(&EmailDryRun, "dry-run", false, "write rendered emails to disk instead of sending them")
+	emailCmd.Flags().StringVar(&EmailDryRunDir, "dry-run-dir", "dry-run-emails", "directory to write rendered emails to in --dry-run mode")
+
+	for _, name := range []string{"smtp-hostname", "smtp-port", "smtp-username", "smtp-password", "email-from", "email-subject"} {
+		if err := viper.BindPFlag(name, emailCmd.Flags().Lookup(name)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	viper.SetEnvPrefix("MUTUAL_AID_TRELLO")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
+}
@@ -0,0 +1,174 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/decarboxy/mutual-aid-trello/credentials"
+	"github.com/spf13/cobra"
+)
+
+const (
+	trelloAuthorizeURL = "https://trello.com/1/authorize"
+	loginTimeout       = 5 * time.Minute
+)
+
+var LoginApiKey string
+
+// callbackPage is served at the local redirect URL. Trello only ever
+// returns the token in the URL fragment, which the server never sees,
+// so we lean on a tiny bit of JS to hand it back to us over a POST.
+const callbackPage = `<!DOCTYPE html>
+<html><body>
+<p>You can close this window and return to the terminal.</p>
+<script>
+  var token = location.hash.replace(/^#token=/, "");
+  fetch("/token", {method: "POST", body: token});
+</script>
+</body></html>`
+
+// readToken reads the whole /token request body. A single r.Body.Read
+// call isn't guaranteed to fill the buffer even when more data is
+// available, so using io.ReadAll here avoids silently truncating the
+// token and saving garbage to credentials.json.
+func readToken(r *http.Request) (string, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// awaitDeviceToken starts a local callback server, prints the
+// authorization URL for the user to open, and waits for Trello to hand
+// back a token, showing a spinner while it waits.
+func awaitDeviceToken(apiKey string) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("starting local callback listener: %w", err)
+	}
+	defer listener.Close()
+
+	redirectUrl := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	tokenCh := make(chan string, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(callbackPage))
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		token, err := readToken(r)
+		if err == nil && token != "" {
+			tokenCh <- token
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Shutdown(context.Background())
+
+	authorizeUrl := fmt.Sprintf(
+		"%s?expiration=never&name=mutual-aid-trello&scope=read&response_type=token&key=%s&return_url=%s&callback_method=fragment",
+		trelloAuthorizeURL, url.QueryEscape(apiKey), url.QueryEscape(redirectUrl))
+
+	fmt.Println("Open the following URL in a browser to authorize this tool:")
+	fmt.Println()
+	fmt.Println(authorizeUrl)
+	fmt.Println()
+
+	spinnerFrames := []rune{'|', '/', '-', '\\'}
+	ticker := time.NewTicker(150 * time.Millisecond)
+	defer ticker.Stop()
+
+	deadline := time.After(loginTimeout)
+	frame := 0
+	for {
+		select {
+		case token := <-tokenCh:
+			fmt.Print("\r")
+			return token, nil
+		case <-ticker.C:
+			fmt.Printf("\rWaiting for authorization %c", spinnerFrames[frame%len(spinnerFrames)])
+			frame++
+		case <-deadline:
+			fmt.Print("\r")
+			return "", errors.New("timed out waiting for authorization; the request may have expired or been denied")
+		}
+	}
+}
+
+// loginCmd represents the login command
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "authorize this tool against Trello and save the resulting token",
+	Run: func(cmd *cobra.Command, args []string) {
+		if LoginApiKey == "" {
+			log.Fatal(errors.New("--api-key is required"))
+		}
+
+		token, err := awaitDeviceToken(LoginApiKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := credentials.Save(&credentials.Credentials{ApiKey: LoginApiKey, Token: token}); err != nil {
+			log.Fatal(err)
+		}
+
+		path, err := credentials.Path()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("Saved credentials to %s\n", path)
+	},
+}
+
+// loadSavedCredentials fills in ApiKey/Token from the credentials file
+// when they weren't passed as flags, so csv/email can be run without
+// repeating --api-key/--token every time.
+func loadSavedCredentials() {
+	if ApiKey != "" && Token != "" {
+		return
+	}
+
+	creds, err := credentials.Load()
+	if err != nil {
+		return
+	}
+
+	if ApiKey == "" {
+		ApiKey = creds.ApiKey
+	}
+	if Token == "" {
+		Token = creds.Token
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+
+	loginCmd.Flags().StringVar(&LoginApiKey, "api-key", "", "A trello API key, from https://trello.com/app-key")
+}
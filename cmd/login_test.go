@@ -0,0 +1,50 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// slowReader trickles its data out one byte at a time, so a single
+// r.Body.Read call can't fill a larger buffer in one pass.
+type slowReader struct {
+	r io.Reader
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return s.r.Read(p)
+}
+
+func TestReadTokenReadsFullBodyAcrossMultipleReads(t *testing.T) {
+	token := strings.Repeat("a", 64)
+	req := httptest.NewRequest(http.MethodPost, "/token", &slowReader{r: strings.NewReader(token)})
+
+	got, err := readToken(req)
+	if err != nil {
+		t.Fatalf("readToken: %v", err)
+	}
+	if got != token {
+		t.Errorf("readToken() = %q (len %d), want %q (len %d)", got, len(got), token, len(token))
+	}
+}
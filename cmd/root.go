@@ -0,0 +1,36 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// rootCmd is the entry point every subcommand attaches itself to.
+var rootCmd = &cobra.Command{
+	Use:   "mutual-aid-trello",
+	Short: "Turn a Trello board of mutual aid requests into CSV exports and confirmation emails",
+}
+
+// Execute adds all child commands to the root command and runs it. This
+// is called by main.main(); it only needs to happen once.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
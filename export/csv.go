@@ -0,0 +1,42 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// CsvExporter writes records as a header row followed by one row per
+// record, same as the tool has always produced.
+type CsvExporter struct{}
+
+func (*CsvExporter) Export(w io.Writer, schema Schema, records []Record) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(schema); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
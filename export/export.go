@@ -0,0 +1,51 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export renders a set of rows to a chosen output format. Every
+// exporter shares the same Schema/Record shape so adding a new format
+// never requires touching the code that builds rows out of a
+// TrelloCard.
+package export
+
+import (
+	"fmt"
+	"io"
+)
+
+// Schema is the ordered set of column names every exporter renders.
+type Schema []string
+
+// Record is one row's values, positionally aligned with a Schema.
+type Record []string
+
+// Exporter renders a schema and its records to a writer.
+type Exporter interface {
+	Export(w io.Writer, schema Schema, records []Record) error
+}
+
+// ForFormat resolves the Exporter for a --format flag value.
+func ForFormat(format string) (Exporter, error) {
+	switch format {
+	case "csv":
+		return &CsvExporter{}, nil
+	case "json":
+		return &JsonExporter{}, nil
+	case "xlsx":
+		return &XlsxExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q (want csv, json, or xlsx)", format)
+	}
+}
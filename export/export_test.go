@@ -0,0 +1,106 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package export
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	fixtureSchema = Schema{"Name", "Email"}
+	fixtureRecords = []Record{
+		{"Ada Lovelace", "ada@example.com"},
+		{"Grace Hopper", "grace@example.com"},
+	}
+)
+
+func goldenFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("reading golden file %s: %v", name, err)
+	}
+	return data
+}
+
+func TestCsvExporter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&CsvExporter{}).Export(&buf, fixtureSchema, fixtureRecords); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	want := goldenFile(t, "cards.csv")
+	if buf.String() != string(want) {
+		t.Errorf("csv output mismatch\ngot:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestJsonExporter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&JsonExporter{}).Export(&buf, fixtureSchema, fixtureRecords); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	want := goldenFile(t, "cards.json")
+	if buf.String() != string(want) {
+		t.Errorf("json output mismatch\ngot:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// TestXlsxExporter checks cell contents rather than raw bytes, since
+// .xlsx files embed timestamps/zip metadata that make byte-for-byte
+// snapshots brittle.
+func TestXlsxExporter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&XlsxExporter{}).Export(&buf, fixtureSchema, fixtureRecords); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("opening generated workbook: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(xlsxSheet)
+	if err != nil {
+		t.Fatalf("reading rows: %v", err)
+	}
+
+	want := [][]string{
+		{"Name", "Email"},
+		{"Ada Lovelace", "ada@example.com"},
+		{"Grace Hopper", "grace@example.com"},
+	}
+
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i := range want {
+		if len(rows[i]) != len(want[i]) {
+			t.Fatalf("row %d: got %v, want %v", i, rows[i], want[i])
+		}
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("row %d col %d: got %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package export
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JsonExporter writes records as a JSON array of {column: value}
+// objects, one per record.
+type JsonExporter struct{}
+
+func (*JsonExporter) Export(w io.Writer, schema Schema, records []Record) error {
+	rows := make([]map[string]string, 0, len(records))
+	for _, record := range records {
+		row := make(map[string]string, len(schema))
+		for i, column := range schema {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package export
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const xlsxSheet = "Sheet1"
+
+// XlsxExporter writes records to a single-sheet .xlsx workbook, header
+// row first.
+type XlsxExporter struct{}
+
+func (*XlsxExporter) Export(w io.Writer, schema Schema, records []Record) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for col, name := range schema {
+		cell, err := excelize.CoordinatesToCellName(col+1, 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(xlsxSheet, cell, name); err != nil {
+			return err
+		}
+	}
+
+	for row, record := range records {
+		for col, value := range record {
+			cell, err := excelize.CoordinatesToCellName(col+1, row+2)
+			if err != nil {
+				return err
+			}
+			if err := f.SetCellValue(xlsxSheet, cell, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := f.WriteTo(w)
+	return err
+}
@@ -0,0 +1,146 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schema describes how to pull fields (Name, Email,
+// Institution, ...) out of a card's description or custom fields,
+// replacing the hardcoded "Name:"/"Email:" prefix splitting that broke
+// whenever the intake form changed or a value itself contained a colon.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is how a Field locates its value on a card.
+type Kind string
+
+const (
+	// KindPrefix matches a description line of the form "<Pattern>: value".
+	KindPrefix Kind = "prefix"
+	// KindRegex matches the description against Pattern; the value is
+	// the first capture group, or the whole match if there isn't one.
+	KindRegex Kind = "regex"
+	// KindCustomField reads the value from the card's custom field
+	// whose ID is CustomFieldID.
+	KindCustomField Kind = "custom_field"
+)
+
+// Field declares how to locate one destination column's value.
+type Field struct {
+	// Column is the destination column this field fills in, e.g.
+	// "Name", "Email", "Institution", "Location", or "Reason".
+	Column        string `yaml:"column" json:"column"`
+	Match         Kind   `yaml:"match" json:"match"`
+	Pattern       string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	CustomFieldID string `yaml:"customFieldId,omitempty" json:"customFieldId,omitempty"`
+	Required      bool   `yaml:"required" json:"required"`
+}
+
+// Schema is the full set of fields describing an intake form.
+type Schema struct {
+	Fields []Field `yaml:"fields" json:"fields"`
+}
+
+// Load reads a Schema from a YAML or JSON file, chosen by extension.
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema %s: %w", path, err)
+	}
+
+	var s Schema
+	switch ext := filepath.Ext(path); ext {
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, &s)
+	case ".json":
+		err = json.Unmarshal(data, &s)
+	default:
+		return nil, fmt.Errorf("unrecognized schema extension %q (want .yml, .yaml, or .json)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Extract resolves every field's value against a card's description
+// and custom field values, keyed by destination column. Any required
+// field that couldn't be resolved is returned in missing.
+func (s *Schema) Extract(description string, customFieldValues map[string]string) (values map[string]string, missing []string) {
+	values = make(map[string]string, len(s.Fields))
+
+	for _, field := range s.Fields {
+		val, found := field.resolve(description, customFieldValues)
+		if found {
+			values[field.Column] = val
+		} else if field.Required {
+			missing = append(missing, field.Column)
+		}
+	}
+	return
+}
+
+func (f *Field) resolve(description string, customFieldValues map[string]string) (string, bool) {
+	switch f.Match {
+	case KindPrefix:
+		prefix := f.Pattern + ":"
+		for _, line := range strings.Split(description, "\n") {
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimSpace(strings.TrimPrefix(line, prefix)), true
+			}
+		}
+		return "", false
+	case KindRegex:
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return "", false
+		}
+		match := re.FindStringSubmatch(description)
+		if match == nil {
+			return "", false
+		}
+		if len(match) > 1 {
+			return strings.TrimSpace(match[1]), true
+		}
+		return strings.TrimSpace(match[0]), true
+	case KindCustomField:
+		val, ok := customFieldValues[f.CustomFieldID]
+		return val, ok
+	default:
+		return "", false
+	}
+}
+
+// Default is the built-in schema used when no --schema file is given.
+// It reproduces the tool's original "Name:"/"Email:"/... prefix
+// splitting, with nothing marked required so behavior is unchanged for
+// callers that don't opt in to a schema file.
+var Default = &Schema{
+	Fields: []Field{
+		{Column: "Name", Match: KindPrefix, Pattern: "Name"},
+		{Column: "Email", Match: KindPrefix, Pattern: "Email"},
+		{Column: "Institution", Match: KindPrefix, Pattern: "Institution"},
+		{Column: "Location", Match: KindPrefix, Pattern: "Location"},
+		{Column: "Reason", Match: KindPrefix, Pattern: "Description"},
+	},
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractPrefixPreservesColons(t *testing.T) {
+	s := &Schema{Fields: []Field{
+		{Column: "Location", Match: KindPrefix, Pattern: "Location", Required: true},
+	}}
+
+	values, missing := s.Extract("Location: Boston, 14:30 pickup", nil)
+	if len(missing) != 0 {
+		t.Fatalf("unexpected missing fields: %v", missing)
+	}
+	if got := values["Location"]; got != "Boston, 14:30 pickup" {
+		t.Errorf("got %q, want %q", got, "Boston, 14:30 pickup")
+	}
+}
+
+func TestExtractRequiredFieldMissing(t *testing.T) {
+	s := &Schema{Fields: []Field{
+		{Column: "Email", Match: KindPrefix, Pattern: "Email", Required: true},
+	}}
+
+	_, missing := s.Extract("Name: Ada Lovelace", nil)
+	if len(missing) != 1 || missing[0] != "Email" {
+		t.Errorf("got missing %v, want [Email]", missing)
+	}
+}
+
+func TestExtractCustomField(t *testing.T) {
+	s := &Schema{Fields: []Field{
+		{Column: "Institution", Match: KindCustomField, CustomFieldID: "abc123", Required: true},
+	}}
+
+	values, missing := s.Extract("", map[string]string{"abc123": "Acme University"})
+	if len(missing) != 0 {
+		t.Fatalf("unexpected missing fields: %v", missing)
+	}
+	if got := values["Institution"]; got != "Acme University" {
+		t.Errorf("got %q, want %q", got, "Acme University")
+	}
+}
+
+func TestValidationErrorLinesUpEveryCard(t *testing.T) {
+	err := &ValidationError{CardErrors: []*CardError{
+		{CardID: "1", CardTitle: "Card One", MissingFields: []string{"Email"}},
+		{CardID: "2", CardTitle: "Card Two", MissingFields: []string{"Name", "Location"}},
+	}}
+
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header plus one line per card:\n%s", len(lines), err.Error())
+	}
+	for i, cardErr := range err.CardErrors {
+		if lines[i+1] != cardErr.Error() {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i+1], cardErr.Error())
+		}
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CardError reports the required fields a single card failed to
+// resolve against a Schema.
+type CardError struct {
+	CardID        string
+	CardTitle     string
+	MissingFields []string
+}
+
+func (e *CardError) Error() string {
+	return fmt.Sprintf("card %q (%s) is missing required fields: %s", e.CardTitle, e.CardID, strings.Join(e.MissingFields, ", "))
+}
+
+// ValidationError aggregates every card that failed schema validation
+// during a run, so operators see the whole picture instead of stopping
+// at the first bad card.
+type ValidationError struct {
+	CardErrors []*CardError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.CardErrors))
+	for i, cardErr := range e.CardErrors {
+		lines[i] = cardErr.Error()
+	}
+	return fmt.Sprintf("%d card(s) failed schema validation:\n%s", len(e.CardErrors), strings.Join(lines, "\n"))
+}
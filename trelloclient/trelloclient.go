@@ -0,0 +1,140 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package trelloclient wraps github.com/adlio/trello so the rest of the
+// tool only ever deals with typed boards/lists/cards/custom
+// fields/actions instead of hand-built query strings. The wrapper also
+// centralizes the rate-limit and retry behavior the library already
+// implements, so callers no longer need their own getAndBackoff loop.
+package trelloclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adlio/trello"
+	"golang.org/x/time/rate"
+)
+
+// Re-export the adlio/trello types we pass around so callers don't need
+// a second import for them.
+type (
+	Board       = trello.Board
+	List        = trello.List
+	Card        = trello.Card
+	CustomField = trello.CustomField
+	Action      = trello.Action
+	Label       = trello.Label
+)
+
+// Client is a thin wrapper around *trello.Client that exposes the
+// typed accessors this tool needs.
+type Client struct {
+	inner   *trello.Client
+	limiter *rate.Limiter
+}
+
+// defaultRateLimit mirrors Trello's own per-token limit of roughly 100
+// requests per 10 seconds, so concurrent callers sharing one Client
+// don't collectively trip the 429 backoff the library already handles.
+const defaultRateLimit = rate.Limit(10)
+
+// New builds a Client authenticated with the given Trello API key and
+// token. Its rate limiter is shared by every CardActions call made
+// through this Client, so concurrent callers (e.g. a worker pool) stay
+// under Trello's per-token limit collectively rather than individually.
+// Board, List, CardsInList and CustomFields do not wait on it.
+func New(apiKey string, token string) *Client {
+	return &Client{
+		inner:   trello.NewClient(apiKey, token),
+		limiter: rate.NewLimiter(defaultRateLimit, int(defaultRateLimit)),
+	}
+}
+
+// Board fetches a board by ID.
+func (c *Client) Board(boardID string) (*Board, error) {
+	board, err := c.inner.GetBoard(boardID, trello.Defaults())
+	if err != nil {
+		return nil, fmt.Errorf("fetching board %s: %w", boardID, err)
+	}
+	return board, nil
+}
+
+// List fetches a single list by ID.
+func (c *Client) List(listID string) (*List, error) {
+	list, err := c.inner.GetList(listID, trello.Defaults())
+	if err != nil {
+		return nil, fmt.Errorf("fetching list %s: %w", listID, err)
+	}
+	return list, nil
+}
+
+// CardsInList returns every card currently filed under the given list,
+// including each card's custom field items so callers can resolve them
+// with CustomFields without a second round trip per card.
+func (c *Client) CardsInList(list *List) ([]*Card, error) {
+	cards, err := list.GetCards(trello.Arguments{"customFieldItems": "true"})
+	if err != nil {
+		return nil, fmt.Errorf("fetching cards for list %s: %w", list.ID, err)
+	}
+	return cards, nil
+}
+
+// CustomFields returns the custom field definitions configured on a
+// board. Pass the result to a Card's own CustomFields method to resolve
+// that card's CustomFieldItems into a map keyed by field name.
+func (c *Client) CustomFields(board *Board) ([]*CustomField, error) {
+	fields, err := board.GetCustomFields()
+	if err != nil {
+		return nil, fmt.Errorf("fetching custom fields for board %s: %w", board.ID, err)
+	}
+	return fields, nil
+}
+
+// CardActions returns the update-card actions recorded against a card,
+// which is how we recover when a card crossed into the completed list.
+// It respects the Client's shared rate limiter and ctx cancellation, so
+// a worker pool fanning this out across many cards can't outrun
+// Trello's per-token rate limit or outlive a sibling worker's error.
+func (c *Client) CardActions(ctx context.Context, card *Card) ([]*Action, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	actions, err := card.GetActions(trello.Arguments{"filter": "updateCard:idList"})
+	if err != nil {
+		return nil, fmt.Errorf("fetching actions for card %s: %w", card.ID, err)
+	}
+	return actions, nil
+}
+
+// WithBaseURL overrides the Trello API base URL the Client talks to,
+// for pointing it at a test server instead of the real API.
+func (c *Client) WithBaseURL(baseURL string) *Client {
+	c.inner.BaseURL = baseURL
+	return c
+}
+
+// HasLabel reports whether a card carries a label with the given name,
+// letting callers classify cards beyond a single hardcoded list.
+func HasLabel(card *Card, labelName string) bool {
+	for _, label := range card.Labels {
+		if label.Name == labelName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2020 Sam DeLuca (sam@decarboxy.com)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package trelloclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return New("key", "token").WithBaseURL(server.URL)
+}
+
+func TestCardsInListRequestsCustomFieldItems(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lists/list1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "list1"}`))
+	})
+	mux.HandleFunc("/lists/list1/cards", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("customFieldItems"); got != "true" {
+			t.Errorf("customFieldItems query param = %q, want true", got)
+		}
+		w.Write([]byte(`[]`))
+	})
+	client := newTestClient(t, mux.ServeHTTP)
+
+	list, err := client.List("list1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if _, err := client.CardsInList(list); err != nil {
+		t.Fatalf("CardsInList: %v", err)
+	}
+}
+
+func TestCustomFieldsReturnsBoardFields(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/boards/board1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "board1"}`))
+	})
+	mux.HandleFunc("/boards/board1/customFields", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "field1", "name": "Amount Paid"}]`))
+	})
+	client := newTestClient(t, mux.ServeHTTP)
+
+	board, err := client.Board("board1")
+	if err != nil {
+		t.Fatalf("Board: %v", err)
+	}
+	fields, err := client.CustomFields(board)
+	if err != nil {
+		t.Fatalf("CustomFields: %v", err)
+	}
+	if len(fields) != 1 || fields[0].Name != "Amount Paid" {
+		t.Fatalf("got %+v, want a single Amount Paid field", fields)
+	}
+}
+
+func TestCardActionsFiltersOnUpdateCard(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lists/list1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "list1"}`))
+	})
+	mux.HandleFunc("/lists/list1/cards", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "card1"}]`))
+	})
+	mux.HandleFunc("/cards/card1/actions", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("filter"); got != "updateCard:idList" {
+			t.Errorf("filter query param = %q, want updateCard:idList", got)
+		}
+		w.Write([]byte(`[]`))
+	})
+	client := newTestClient(t, mux.ServeHTTP)
+
+	list, err := client.List("list1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	cards, err := client.CardsInList(list)
+	if err != nil {
+		t.Fatalf("CardsInList: %v", err)
+	}
+	if _, err := client.CardActions(context.Background(), cards[0]); err != nil {
+		t.Fatalf("CardActions: %v", err)
+	}
+}
+
+func TestCardActionsRespectsCanceledContext(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("CardActions made a request despite a canceled context")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.CardActions(ctx, &Card{ID: "card1"}); err == nil {
+		t.Fatal("expected an error from a canceled context, got nil")
+	}
+}
+
+func TestHasLabel(t *testing.T) {
+	card := &Card{Labels: []*Label{{Name: "Urgent"}}}
+
+	if !HasLabel(card, "Urgent") {
+		t.Error("HasLabel(card, \"Urgent\") = false, want true")
+	}
+	if HasLabel(card, "Nonexistent") {
+		t.Error("HasLabel(card, \"Nonexistent\") = true, want false")
+	}
+}